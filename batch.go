@@ -0,0 +1,278 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unrecoverableErrors are substrings of server error messages that indicate
+// a batch can never succeed and should be dropped rather than retried.
+var unrecoverableErrors = []string{
+	"database not found",
+	"unable to parse",
+}
+
+// BatchWriterConfig controls the batching, retry, and backpressure behavior
+// of a BatchWriter.
+type BatchWriterConfig struct {
+	// MaxBatchSize is the number of Series flushed to the server per write.
+	MaxBatchSize int
+	// FlushInterval is how often queued series are flushed even if
+	// MaxBatchSize hasn't been reached.
+	FlushInterval time.Duration
+	// MaxQueueSize bounds the number of series Add will buffer in memory.
+	MaxQueueSize int
+	// MaxRetries is the number of retry attempts for a batch that fails
+	// with a recoverable error before it is handed to OnBatchError.
+	MaxRetries int
+	// SpillDir, if set, is a directory where in-flight batches are
+	// persisted so they survive a process restart (hinted handoff).
+	SpillDir string
+	// OnBatchError, if set, is called with a batch and the error that
+	// caused it to be given up on (unrecoverable, or retries exhausted).
+	OnBatchError func(series []*Series, err error)
+}
+
+var defaultBatchWriterConfig = BatchWriterConfig{
+	MaxBatchSize:  500,
+	FlushInterval: time.Second,
+	MaxQueueSize:  10000,
+	MaxRetries:    5,
+}
+
+// BatchWriter buffers Series written via Add and flushes them to a Client
+// on a background goroutine, batching by size or interval and retrying
+// recoverable failures with exponential backoff and jitter.
+type BatchWriter struct {
+	client *Client
+	config BatchWriterConfig
+
+	mu    sync.Mutex
+	queue []*Series
+
+	flush     chan chan error
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewBatchWriter creates a BatchWriter writing through client. A nil config
+// uses defaultBatchWriterConfig.
+func NewBatchWriter(client *Client, config *BatchWriterConfig) *BatchWriter {
+	cfg := defaultBatchWriterConfig
+	if config != nil {
+		if config.MaxBatchSize > 0 {
+			cfg.MaxBatchSize = config.MaxBatchSize
+		}
+		if config.FlushInterval > 0 {
+			cfg.FlushInterval = config.FlushInterval
+		}
+		if config.MaxQueueSize > 0 {
+			cfg.MaxQueueSize = config.MaxQueueSize
+		}
+		if config.MaxRetries > 0 {
+			cfg.MaxRetries = config.MaxRetries
+		}
+		cfg.SpillDir = config.SpillDir
+		cfg.OnBatchError = config.OnBatchError
+	}
+
+	self := &BatchWriter{
+		client: client,
+		config: cfg,
+		flush:  make(chan chan error),
+		closed: make(chan struct{}),
+	}
+	self.restoreSpill()
+	self.wg.Add(1)
+	go self.run()
+	return self
+}
+
+// Add enqueues series for asynchronous delivery. It returns an error if the
+// in-memory queue is already at MaxQueueSize.
+func (self *BatchWriter) Add(series *Series) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if len(self.queue) >= self.config.MaxQueueSize {
+		return fmt.Errorf("BatchWriter queue is full (%d series)", self.config.MaxQueueSize)
+	}
+	self.queue = append(self.queue, series)
+	return nil
+}
+
+func (self *BatchWriter) run() {
+	defer self.wg.Done()
+	ticker := time.NewTicker(self.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			self.flushAll()
+		case done := <-self.flush:
+			self.flushAll()
+			if done != nil {
+				done <- nil
+			}
+		case <-self.closed:
+			self.flushAll()
+			return
+		}
+	}
+}
+
+func (self *BatchWriter) takeBatch() []*Series {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if len(self.queue) == 0 {
+		return nil
+	}
+	n := self.config.MaxBatchSize
+	if n <= 0 || n > len(self.queue) {
+		n = len(self.queue)
+	}
+	batch := self.queue[:n]
+	self.queue = self.queue[n:]
+	return batch
+}
+
+func (self *BatchWriter) flushAll() {
+	for {
+		batch := self.takeBatch()
+		if batch == nil {
+			return
+		}
+		self.writeWithRetry(batch)
+	}
+}
+
+func (self *BatchWriter) writeWithRetry(batch []*Series) {
+	spillPath := self.spill(batch)
+
+	var err error
+	for attempt := 0; attempt <= self.config.MaxRetries; attempt++ {
+		err = self.client.WriteSeries(batch)
+		if err == nil {
+			break
+		}
+		if !isRetryable(err) {
+			break
+		}
+		if attempt < self.config.MaxRetries {
+			time.Sleep(backoff(attempt))
+		}
+	}
+
+	if err == nil {
+		self.unspill(spillPath)
+		return
+	}
+	// Give up: either unrecoverable or retries exhausted. Either way the
+	// batch is done being retried, so its spill file must not come back
+	// on the next restart.
+	self.unspill(spillPath)
+	if self.config.OnBatchError != nil {
+		self.config.OnBatchError(batch, err)
+	}
+}
+
+func isRetryable(err error) bool {
+	msg := err.Error()
+	for _, s := range unrecoverableErrors {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func backoff(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)+1))
+}
+
+func (self *BatchWriter) spill(batch []*Series) string {
+	if self.config.SpillDir == "" {
+		return ""
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(self.config.SpillDir, fmt.Sprintf("%d.json", time.Now().UnixNano()))
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return ""
+	}
+	return path
+}
+
+func (self *BatchWriter) unspill(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
+}
+
+func (self *BatchWriter) restoreSpill() {
+	if self.config.SpillDir == "" {
+		return
+	}
+	files, err := ioutil.ReadDir(self.config.SpillDir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(self.config.SpillDir, f.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var batch []*Series
+		if err := json.Unmarshal(data, &batch); err != nil {
+			continue
+		}
+		self.queue = append(self.queue, batch...)
+		os.Remove(path)
+	}
+}
+
+// Flush blocks until all currently queued series have been written, or ctx
+// is done.
+func (self *BatchWriter) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case self.flush <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-self.closed:
+		return fmt.Errorf("BatchWriter is closed")
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the background flush loop after writing any queued series.
+// It is safe to call concurrently or more than once.
+func (self *BatchWriter) Close() error {
+	self.closeOnce.Do(func() {
+		close(self.closed)
+	})
+	self.wg.Wait()
+	return nil
+}
@@ -0,0 +1,79 @@
+package influxdb
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableClassifiesUnrecoverableErrors(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{errors.New("Server returned (404): database not found: mydb"), false},
+		{errors.New("Server returned (400): unable to parse query"), false},
+		{errors.New("Server returned (500): internal server error"), true},
+		{errors.New("dial tcp: connection refused"), true},
+	}
+	for _, c := range cases {
+		if got := isRetryable(c.err); got != c.retryable {
+			t.Errorf("isRetryable(%q) = %v, want %v", c.err, got, c.retryable)
+		}
+	}
+}
+
+func TestBackoffIsBoundedAndGrowsWithAttempt(t *testing.T) {
+	for attempt := 0; attempt < 5; attempt++ {
+		base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+		for i := 0; i < 20; i++ {
+			d := backoff(attempt)
+			if d < base || d > 2*base {
+				t.Fatalf("backoff(%d) = %s, want in [%s, %s]", attempt, d, base, 2*base)
+			}
+		}
+	}
+}
+
+func TestSpillRoundTripsThroughRestoreSpill(t *testing.T) {
+	dir := t.TempDir()
+	bw := &BatchWriter{config: BatchWriterConfig{SpillDir: dir}}
+
+	batch := []*Series{{Name: "cpu", Columns: []string{"value"}, Points: [][]interface{}{{1.0}}}}
+	path := bw.spill(batch)
+	if path == "" {
+		t.Fatal("spill returned an empty path")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("spill did not write %s: %s", path, err)
+	}
+
+	restored := &BatchWriter{config: BatchWriterConfig{SpillDir: dir}}
+	restored.restoreSpill()
+
+	if len(restored.queue) != 1 {
+		t.Fatalf("restoreSpill loaded %d series, want 1", len(restored.queue))
+	}
+	if restored.queue[0].Name != "cpu" {
+		t.Errorf("restoreSpill loaded series named %q, want %q", restored.queue[0].Name, "cpu")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("restoreSpill did not remove the spill file after loading it")
+	}
+}
+
+func TestCloseSafeUnderConcurrentCallers(t *testing.T) {
+	bw := NewBatchWriter(&Client{}, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bw.Close()
+		}()
+	}
+	wg.Wait()
+}
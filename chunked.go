@@ -0,0 +1,62 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// ChunkedResponse streams the Series of a chunked query response one
+// object at a time instead of buffering the whole payload in memory.
+type ChunkedResponse struct {
+	decoder *json.Decoder
+	body    io.ReadCloser
+}
+
+// QueryChunked runs query with chunked=true (and the given chunk_size) and
+// returns a ChunkedResponse that decodes Series from the response body as
+// they arrive.
+func (self *Client) QueryChunked(query string, chunkSize int, precision ...TimePrecision) (*ChunkedResponse, error) {
+	return self.QueryChunkedContext(context.Background(), query, chunkSize, precision...)
+}
+
+// QueryChunkedContext is the context-aware variant of QueryChunked.
+func (self *Client) QueryChunkedContext(ctx context.Context, query string, chunkSize int, precision ...TimePrecision) (*ChunkedResponse, error) {
+	values := url.Values{}
+	if len(precision) > 0 {
+		values.Set("time_precision", string(precision[0]))
+	}
+	values.Set("q", query)
+	values.Set("chunked", "true")
+	if chunkSize > 0 {
+		values.Set("chunk_size", strconv.Itoa(chunkSize))
+	}
+	u := self.buildUrl("/db/"+self.database+"/series", values)
+
+	resp, err := self.get(ctx, u)
+	if err := responseToError(resp, err, false); err != nil {
+		return nil, err
+	}
+
+	return &ChunkedResponse{
+		decoder: json.NewDecoder(resp.Body),
+		body:    resp.Body,
+	}, nil
+}
+
+// Next decodes and returns the next Series in the stream. It returns
+// io.EOF once the response body is exhausted.
+func (self *ChunkedResponse) Next() (*Series, error) {
+	series := &Series{}
+	if err := self.decoder.Decode(series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Close releases the underlying HTTP response body.
+func (self *ChunkedResponse) Close() error {
+	return self.body.Close()
+}
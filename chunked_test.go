@@ -0,0 +1,70 @@
+package influxdb
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func newChunkedResponse(body string) *ChunkedResponse {
+	rc := ioutil.NopCloser(strings.NewReader(body))
+	return &ChunkedResponse{decoder: json.NewDecoder(rc), body: rc}
+}
+
+func TestChunkedResponseNextStreamsEachSeries(t *testing.T) {
+	resp := newChunkedResponse(`{"name":"cpu","columns":["value"],"points":[[1]]}` +
+		`{"name":"mem","columns":["value"],"points":[[2]]}`)
+
+	first, err := resp.Next()
+	if err != nil {
+		t.Fatalf("Next returned error: %s", err)
+	}
+	if first.Name != "cpu" {
+		t.Errorf("first.Name = %q, want %q", first.Name, "cpu")
+	}
+
+	second, err := resp.Next()
+	if err != nil {
+		t.Fatalf("Next returned error: %s", err)
+	}
+	if second.Name != "mem" {
+		t.Errorf("second.Name = %q, want %q", second.Name, "mem")
+	}
+
+	if _, err := resp.Next(); err != io.EOF {
+		t.Fatalf("Next at end of stream returned %v, want io.EOF", err)
+	}
+}
+
+func TestChunkedResponseNextSurfacesDecodeErrors(t *testing.T) {
+	resp := newChunkedResponse(`{not valid json`)
+
+	if _, err := resp.Next(); err == nil || err == io.EOF {
+		t.Fatalf("Next returned %v, want a decode error", err)
+	}
+}
+
+func TestChunkedResponseCloseClosesBody(t *testing.T) {
+	closed := false
+	rc := &closeTrackingReader{Reader: strings.NewReader(""), onClose: func() { closed = true }}
+	resp := &ChunkedResponse{decoder: json.NewDecoder(rc), body: rc}
+
+	if err := resp.Close(); err != nil {
+		t.Fatalf("Close returned error: %s", err)
+	}
+	if !closed {
+		t.Error("Close did not close the underlying response body")
+	}
+}
+
+type closeTrackingReader struct {
+	*strings.Reader
+	onClose func()
+}
+
+func (c *closeTrackingReader) Close() error {
+	c.onClose()
+	return nil
+}
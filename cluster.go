@@ -0,0 +1,244 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClusterStrategy selects how a Cluster picks a healthy node for a given
+// request.
+type ClusterStrategy string
+
+const (
+	RoundRobin   ClusterStrategy = "round-robin"
+	Random       ClusterStrategy = "random"
+	FirstHealthy ClusterStrategy = "first-healthy"
+)
+
+// ClusterOptions configures a Cluster's load-balancing strategy and health
+// checking.
+type ClusterOptions struct {
+	Strategy            ClusterStrategy
+	HealthCheckInterval time.Duration
+}
+
+var defaultClusterOptions = ClusterOptions{
+	Strategy:            FirstHealthy,
+	HealthCheckInterval: 10 * time.Second,
+}
+
+type clusterNode struct {
+	client *Client
+
+	mu      sync.RWMutex
+	healthy bool
+}
+
+func (self *clusterNode) setHealthy(healthy bool) {
+	self.mu.Lock()
+	self.healthy = healthy
+	self.mu.Unlock()
+}
+
+func (self *clusterNode) isHealthy() bool {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.healthy
+}
+
+// Cluster load-balances writes and queries across a set of InfluxDB hosts,
+// failing over to other healthy nodes when one stops responding.
+type Cluster struct {
+	nodes   []*clusterNode
+	options ClusterOptions
+
+	mu   sync.Mutex
+	next int
+
+	closed chan struct{}
+}
+
+// NewCluster builds a Cluster over the given endpoints. A Cluster offers
+// WriteSeries, Query and Ping (and their Context variants) with the same
+// signatures as on Client; the other Client methods are not available on it.
+func NewCluster(configs []*ClientConfig, options ClusterOptions) (*Cluster, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("influxdb: NewCluster requires at least one ClientConfig")
+	}
+	if options.Strategy == "" {
+		options.Strategy = defaultClusterOptions.Strategy
+	}
+	if options.HealthCheckInterval <= 0 {
+		options.HealthCheckInterval = defaultClusterOptions.HealthCheckInterval
+	}
+
+	nodes := make([]*clusterNode, 0, len(configs))
+	for _, config := range configs {
+		client, err := NewClient(config)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &clusterNode{client: client, healthy: true})
+	}
+
+	self := &Cluster{
+		nodes:   nodes,
+		options: options,
+		closed:  make(chan struct{}),
+	}
+	go self.healthCheckLoop()
+	return self, nil
+}
+
+func (self *Cluster) healthCheckLoop() {
+	ticker := time.NewTicker(self.options.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, node := range self.nodes {
+				node.setHealthy(node.client.Ping() == nil)
+			}
+		case <-self.closed:
+			return
+		}
+	}
+}
+
+func (self *Cluster) healthyNodes() []*clusterNode {
+	healthy := make([]*clusterNode, 0, len(self.nodes))
+	for _, node := range self.nodes {
+		if node.isHealthy() {
+			healthy = append(healthy, node)
+		}
+	}
+	return healthy
+}
+
+func (self *Cluster) pickNode(candidates []*clusterNode) *clusterNode {
+	switch self.options.Strategy {
+	case Random:
+		return candidates[rand.Intn(len(candidates))]
+	case FirstHealthy:
+		return candidates[0]
+	default: // RoundRobin
+		self.mu.Lock()
+		node := candidates[self.next%len(candidates)]
+		self.next++
+		self.mu.Unlock()
+		return node
+	}
+}
+
+func removeNode(candidates []*clusterNode, target *clusterNode) []*clusterNode {
+	remaining := make([]*clusterNode, 0, len(candidates)-1)
+	for _, node := range candidates {
+		if node != target {
+			remaining = append(remaining, node)
+		}
+	}
+	return remaining
+}
+
+// isNodeDown reports whether err indicates the node itself is unreachable
+// (connection refused, timeout, DNS failure, ...) as opposed to the server
+// having handled the request and rejected it (bad query, auth failure, ...).
+// responseToError returns the raw transport error verbatim in the former
+// case and a formatted "Server returned (...)" error in the latter, so a
+// server-side rejection never looks like a down node here. Any other error
+// without that prefix (a payload that fails to marshal, a response that
+// fails to decode, a cancelled or expired ctx) is treated as a down node.
+func isNodeDown(err error) bool {
+	return !strings.HasPrefix(err.Error(), "Server returned")
+}
+
+// withFailover tries do against healthy nodes in strategy order. A node is
+// marked unhealthy and removed from the candidate set only when do fails
+// with an error that indicates the node itself is down; a request-level
+// error (a malformed query, say) would fail identically on every node, so
+// it is returned immediately instead of cascading into failing over the
+// whole cluster.
+func (self *Cluster) withFailover(do func(client *Client) error) error {
+	remaining := self.healthyNodes()
+	if len(remaining) == 0 {
+		return errors.New("influxdb: no healthy nodes available")
+	}
+
+	var err error
+	for len(remaining) > 0 {
+		node := self.pickNode(remaining)
+		err = do(node.client)
+		if err == nil {
+			return nil
+		}
+		if !isNodeDown(err) {
+			return err
+		}
+		node.setHealthy(false)
+		remaining = removeNode(remaining, node)
+	}
+	return err
+}
+
+// WriteSeries writes to a healthy node, failing over to the next healthy
+// node if the write fails because the node is down (see withFailover).
+func (self *Cluster) WriteSeries(series []*Series) error {
+	return self.withFailover(func(client *Client) error {
+		return client.WriteSeries(series)
+	})
+}
+
+// WriteSeriesContext is the context-aware variant of WriteSeries.
+func (self *Cluster) WriteSeriesContext(ctx context.Context, series []*Series) error {
+	return self.withFailover(func(client *Client) error {
+		return client.WriteSeriesContext(ctx, series)
+	})
+}
+
+// Query runs query against a healthy node, failing over to the next
+// healthy node if the query fails because the node is down (see
+// withFailover).
+func (self *Cluster) Query(query string, precision ...TimePrecision) ([]*Series, error) {
+	return self.QueryContext(context.Background(), query, precision...)
+}
+
+// QueryContext is the context-aware variant of Query.
+func (self *Cluster) QueryContext(ctx context.Context, query string, precision ...TimePrecision) ([]*Series, error) {
+	var result []*Series
+	err := self.withFailover(func(client *Client) error {
+		series, err := client.QueryContext(ctx, query, precision...)
+		if err != nil {
+			return err
+		}
+		result = series
+		return nil
+	})
+	return result, err
+}
+
+// Ping reports whether at least one node in the cluster is healthy.
+func (self *Cluster) Ping() error {
+	return self.PingContext(context.Background())
+}
+
+// PingContext is the context-aware variant of Ping.
+func (self *Cluster) PingContext(ctx context.Context) error {
+	if len(self.healthyNodes()) == 0 {
+		return errors.New("influxdb: no healthy nodes available")
+	}
+	return nil
+}
+
+// Close stops the background health check loop.
+func (self *Cluster) Close() error {
+	select {
+	case <-self.closed:
+	default:
+		close(self.closed)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package influxdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newTestCluster(n int, strategy ClusterStrategy) *Cluster {
+	nodes := make([]*clusterNode, n)
+	for i := range nodes {
+		nodes[i] = &clusterNode{client: &Client{}, healthy: true}
+	}
+	return &Cluster{
+		nodes:   nodes,
+		options: ClusterOptions{Strategy: strategy},
+		closed:  make(chan struct{}),
+	}
+}
+
+func TestWithFailoverRemovesFailingNodeAndRetriesNext(t *testing.T) {
+	cluster := newTestCluster(3, FirstHealthy)
+	failing := cluster.nodes[0]
+
+	var tried []*clusterNode
+	err := cluster.withFailover(func(client *Client) error {
+		var node *clusterNode
+		for _, n := range cluster.nodes {
+			if n.client == client {
+				node = n
+				break
+			}
+		}
+		tried = append(tried, node)
+		if node == failing {
+			return errors.New("dial tcp: connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withFailover returned error: %s", err)
+	}
+	if len(tried) != 2 {
+		t.Fatalf("withFailover tried %d nodes, want 2", len(tried))
+	}
+	if tried[0] != failing {
+		t.Fatalf("withFailover did not try the first node first")
+	}
+	if failing.isHealthy() {
+		t.Error("failing node was not marked unhealthy")
+	}
+	if cluster.nodes[1].isHealthy() != true || cluster.nodes[2].isHealthy() != true {
+		t.Error("nodes that did not fail should remain healthy")
+	}
+}
+
+func TestWithFailoverExhaustsAllNodes(t *testing.T) {
+	cluster := newTestCluster(2, FirstHealthy)
+	boom := errors.New("dial tcp: connection refused")
+
+	err := cluster.withFailover(func(client *Client) error {
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("withFailover returned %v, want %v", err, boom)
+	}
+	for _, node := range cluster.nodes {
+		if node.isHealthy() {
+			t.Error("all nodes should be marked unhealthy after exhausting failover on transport errors")
+		}
+	}
+}
+
+func TestWithFailoverDoesNotCascadeOnRequestLevelErrors(t *testing.T) {
+	cluster := newTestCluster(3, FirstHealthy)
+	serverErr := fmt.Errorf("Server returned (400): unable to parse query")
+
+	var calls int
+	err := cluster.withFailover(func(client *Client) error {
+		calls++
+		return serverErr
+	})
+	if err != serverErr {
+		t.Fatalf("withFailover returned %v, want %v", err, serverErr)
+	}
+	if calls != 1 {
+		t.Errorf("withFailover called do %d times, want 1 (should not retry a request-level error on other nodes)", calls)
+	}
+	for _, node := range cluster.nodes {
+		if !node.isHealthy() {
+			t.Error("a request-level error should not mark any node unhealthy")
+		}
+	}
+}
+
+func TestWithFailoverNoHealthyNodes(t *testing.T) {
+	cluster := newTestCluster(1, FirstHealthy)
+	cluster.nodes[0].setHealthy(false)
+
+	err := cluster.withFailover(func(client *Client) error {
+		t.Fatal("do should not be called with no healthy nodes")
+		return nil
+	})
+	if err == nil {
+		t.Error("withFailover returned nil error with no healthy nodes")
+	}
+}
@@ -2,13 +2,27 @@ package influxdb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 )
 
+// AuthMode selects how a Client authenticates its requests.
+type AuthMode int
+
+const (
+	// AuthQueryString sends credentials as u/p query string parameters,
+	// the server's legacy authentication scheme.
+	AuthQueryString AuthMode = iota
+	// AuthBasic sends credentials via the HTTP Basic authentication
+	// header instead, so they don't end up in proxy or access logs.
+	AuthBasic
+)
+
 type Client struct {
 	host       string
 	username   string
@@ -16,6 +30,7 @@ type Client struct {
 	database   string
 	httpClient *http.Client
 	schema     string
+	authMode   AuthMode
 }
 
 type ClientConfig struct {
@@ -25,6 +40,7 @@ type ClientConfig struct {
 	Database   string
 	HttpClient *http.Client
 	IsSecure   bool
+	AuthMode   AuthMode
 }
 
 var defaults *ClientConfig
@@ -59,15 +75,69 @@ func NewClient(config *ClientConfig) (*Client, error) {
 	if config.IsSecure {
 		schema = "https"
 	}
-	return &Client{host, username, passowrd, database, config.HttpClient, schema}, nil
+	return &Client{host, username, passowrd, database, config.HttpClient, schema, config.AuthMode}, nil
+}
+
+// buildUrl assembles a request URL for path. When authMode is
+// AuthQueryString it adds u/p credentials to query; when it is AuthBasic,
+// credentials are left out of the URL entirely and doRequest authenticates
+// via the Basic auth header instead, so they never end up in query strings
+// or the logs that capture them.
+func (self *Client) buildUrl(path string, query url.Values) string {
+	if self.authMode == AuthBasic {
+		if query == nil {
+			query = url.Values{}
+		}
+		u := url.URL{Scheme: self.schema, Host: self.host, Path: path, RawQuery: query.Encode()}
+		return u.String()
+	}
+	return self.buildUrlWithUserAndPass(path, self.username, self.password, query)
+}
+
+// buildUrlWithUserAndPass is like buildUrl but always authenticates via the
+// given username/password query parameters, regardless of AuthMode. It
+// exists for endpoints (such as AuthenticateDatabaseUser) that are
+// explicitly testing query-string credentials.
+func (self *Client) buildUrlWithUserAndPass(path, username, password string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("u", username)
+	query.Set("p", password)
+	u := url.URL{Scheme: self.schema, Host: self.host, Path: path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// doRequest builds and issues an HTTP request bound to ctx so that callers
+// can cancel it or enforce a deadline.
+func (self *Client) doRequest(ctx context.Context, method, rawurl, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawurl, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if self.authMode == AuthBasic {
+		req.SetBasicAuth(self.username, self.password)
+	}
+	return self.httpClient.Do(req.WithContext(ctx))
+}
+
+func (self *Client) post(ctx context.Context, rawurl string, payload interface{}) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return self.doRequest(ctx, "POST", rawurl, "application/json", bytes.NewBuffer(data))
 }
 
-func (self *Client) getUrl(path string) string {
-	return self.getUrlWithUserAndPass(path, self.username, self.password)
+func (self *Client) get(ctx context.Context, rawurl string) (*http.Response, error) {
+	return self.doRequest(ctx, "GET", rawurl, "", nil)
 }
 
-func (self *Client) getUrlWithUserAndPass(path, username, password string) string {
-	return fmt.Sprintf("%s://%s%s?u=%s&p=%s", self.schema, self.host, path, username, password)
+func (self *Client) del(ctx context.Context, rawurl string) (*http.Response, error) {
+	return self.doRequest(ctx, "DELETE", rawurl, "", nil)
 }
 
 func responseToError(response *http.Response, err error, closeResponse bool) error {
@@ -89,32 +159,27 @@ func responseToError(response *http.Response, err error, closeResponse bool) err
 }
 
 func (self *Client) CreateDatabase(name string) error {
-	url := self.getUrl("/db")
-	payload := map[string]string{"name": name}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
-	return responseToError(resp, err, true)
+	return self.CreateDatabaseContext(context.Background(), name)
 }
 
-func (self *Client) del(url string) (*http.Response, error) {
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	return self.httpClient.Do(req)
+func (self *Client) CreateDatabaseContext(ctx context.Context, name string) error {
+	u := self.buildUrl("/db", nil)
+	resp, err := self.post(ctx, u, map[string]string{"name": name})
+	return responseToError(resp, err, true)
 }
 
 func (self *Client) DeleteDatabase(name string) error {
-	url := self.getUrl("/db/" + name)
-	resp, err := self.del(url)
+	return self.DeleteDatabaseContext(context.Background(), name)
+}
+
+func (self *Client) DeleteDatabaseContext(ctx context.Context, name string) error {
+	u := self.buildUrl("/db/"+name, nil)
+	resp, err := self.del(ctx, u)
 	return responseToError(resp, err, true)
 }
 
-func (self *Client) listSomething(url string) ([]map[string]interface{}, error) {
-	resp, err := self.httpClient.Get(url)
+func (self *Client) listSomething(ctx context.Context, rawurl string) ([]map[string]interface{}, error) {
+	resp, err := self.get(ctx, rawurl)
 	err = responseToError(resp, err, false)
 	if err != nil {
 		return nil, err
@@ -133,56 +198,63 @@ func (self *Client) listSomething(url string) ([]map[string]interface{}, error)
 }
 
 func (self *Client) GetDatabaseList() ([]map[string]interface{}, error) {
-	url := self.getUrl("/db")
-	return self.listSomething(url)
+	return self.GetDatabaseListContext(context.Background())
+}
+
+func (self *Client) GetDatabaseListContext(ctx context.Context) ([]map[string]interface{}, error) {
+	return self.listSomething(ctx, self.buildUrl("/db", nil))
 }
 
 func (self *Client) CreateClusterAdmin(name, password string) error {
-	url := self.getUrl("/cluster_admins")
-	payload := map[string]string{"name": name, "password": password}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	return self.CreateClusterAdminContext(context.Background(), name, password)
+}
+
+func (self *Client) CreateClusterAdminContext(ctx context.Context, name, password string) error {
+	u := self.buildUrl("/cluster_admins", nil)
+	resp, err := self.post(ctx, u, map[string]string{"name": name, "password": password})
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) UpdateClusterAdmin(name, password string) error {
-	url := self.getUrl("/cluster_admins/" + name)
-	payload := map[string]string{"password": password}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	return self.UpdateClusterAdminContext(context.Background(), name, password)
+}
+
+func (self *Client) UpdateClusterAdminContext(ctx context.Context, name, password string) error {
+	u := self.buildUrl("/cluster_admins/"+name, nil)
+	resp, err := self.post(ctx, u, map[string]string{"password": password})
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) DeleteClusterAdmin(name string) error {
-	url := self.getUrl("/cluster_admins/" + name)
-	resp, err := self.del(url)
+	return self.DeleteClusterAdminContext(context.Background(), name)
+}
+
+func (self *Client) DeleteClusterAdminContext(ctx context.Context, name string) error {
+	u := self.buildUrl("/cluster_admins/"+name, nil)
+	resp, err := self.del(ctx, u)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) GetClusterAdminList() ([]map[string]interface{}, error) {
-	url := self.getUrl("/cluster_admins")
-	return self.listSomething(url)
+	return self.GetClusterAdminListContext(context.Background())
+}
+
+func (self *Client) GetClusterAdminListContext(ctx context.Context) ([]map[string]interface{}, error) {
+	return self.listSomething(ctx, self.buildUrl("/cluster_admins", nil))
 }
 
 func (self *Client) CreateDatabaseUser(database, name, password string) error {
-	url := self.getUrl("/db/" + database + "/users")
-	payload := map[string]string{"name": name, "password": password}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	return self.CreateDatabaseUserContext(context.Background(), database, name, password)
+}
+
+func (self *Client) CreateDatabaseUserContext(ctx context.Context, database, name, password string) error {
+	u := self.buildUrl("/db/"+database+"/users", nil)
+	resp, err := self.post(ctx, u, map[string]string{"name": name, "password": password})
 	return responseToError(resp, err, true)
 }
 
-func (self *Client) updateDatabaseUserCommon(database, name string, password *string, isAdmin *bool) error {
-	url := self.getUrl("/db/" + database + "/users/" + name)
+func (self *Client) updateDatabaseUserCommon(ctx context.Context, database, name string, password *string, isAdmin *bool) error {
+	u := self.buildUrl("/db/"+database+"/users/"+name, nil)
 	payload := map[string]interface{}{}
 	if password != nil {
 		payload["password"] = *password
@@ -190,31 +262,42 @@ func (self *Client) updateDatabaseUserCommon(database, name string, password *st
 	if isAdmin != nil {
 		payload["admin"] = *isAdmin
 	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.post(ctx, u, payload)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) UpdateDatabaseUser(database, name, password string) error {
-	return self.updateDatabaseUserCommon(database, name, &password, nil)
+	return self.UpdateDatabaseUserContext(context.Background(), database, name, password)
+}
+
+func (self *Client) UpdateDatabaseUserContext(ctx context.Context, database, name, password string) error {
+	return self.updateDatabaseUserCommon(ctx, database, name, &password, nil)
 }
 
 func (self *Client) DeleteDatabaseUser(database, name string) error {
-	url := self.getUrl("/db/" + database + "/users/" + name)
-	resp, err := self.del(url)
+	return self.DeleteDatabaseUserContext(context.Background(), database, name)
+}
+
+func (self *Client) DeleteDatabaseUserContext(ctx context.Context, database, name string) error {
+	u := self.buildUrl("/db/"+database+"/users/"+name, nil)
+	resp, err := self.del(ctx, u)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) GetDatabaseUserList(database string) ([]map[string]interface{}, error) {
-	url := self.getUrl("/db/" + database + "/users")
-	return self.listSomething(url)
+	return self.GetDatabaseUserListContext(context.Background(), database)
+}
+
+func (self *Client) GetDatabaseUserListContext(ctx context.Context, database string) ([]map[string]interface{}, error) {
+	return self.listSomething(ctx, self.buildUrl("/db/"+database+"/users", nil))
 }
 
 func (self *Client) AlterDatabasePrivilege(database, name string, isAdmin bool) error {
-	return self.updateDatabaseUserCommon(database, name, nil, &isAdmin)
+	return self.AlterDatabasePrivilegeContext(context.Background(), database, name, isAdmin)
+}
+
+func (self *Client) AlterDatabasePrivilegeContext(ctx context.Context, database, name string, isAdmin bool) error {
+	return self.updateDatabaseUserCommon(ctx, database, name, nil, &isAdmin)
 }
 
 type TimePrecision string
@@ -226,34 +309,47 @@ const (
 )
 
 func (self *Client) WriteSeries(series []*Series) error {
-	return self.writeSeriesCommon(series, nil)
+	return self.WriteSeriesContext(context.Background(), series)
+}
+
+func (self *Client) WriteSeriesContext(ctx context.Context, series []*Series) error {
+	return self.writeSeriesCommon(ctx, series, nil)
 }
 
 func (self *Client) WriteSeriesWithTimePrecision(series []*Series, timePrecision TimePrecision) error {
-	return self.writeSeriesCommon(series, map[string]string{"time_precision": string(timePrecision)})
+	return self.WriteSeriesWithTimePrecisionContext(context.Background(), series, timePrecision)
 }
 
-func (self *Client) writeSeriesCommon(series []*Series, options map[string]string) error {
+func (self *Client) WriteSeriesWithTimePrecisionContext(ctx context.Context, series []*Series, timePrecision TimePrecision) error {
+	return self.writeSeriesCommon(ctx, series, map[string]string{"time_precision": string(timePrecision)})
+}
+
+func (self *Client) writeSeriesCommon(ctx context.Context, series []*Series, options map[string]string) error {
 	data, err := json.Marshal(series)
 	if err != nil {
 		return err
 	}
-	url := self.getUrl("/db/" + self.database + "/series")
+	query := url.Values{}
 	for name, value := range options {
-		url += fmt.Sprintf("&%s=%s", name, value)
+		query.Set(name, value)
 	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	u := self.buildUrl("/db/"+self.database+"/series", query)
+	resp, err := self.doRequest(ctx, "POST", u, "application/json", bytes.NewBuffer(data))
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) Query(query string, precision ...TimePrecision) ([]*Series, error) {
-	escapedQuery := url.QueryEscape(query)
-	url := self.getUrl("/db/" + self.database + "/series")
+	return self.QueryContext(context.Background(), query, precision...)
+}
+
+func (self *Client) QueryContext(ctx context.Context, query string, precision ...TimePrecision) ([]*Series, error) {
+	values := url.Values{}
 	if len(precision) > 0 {
-		url += "&time_precision=" + string(precision[0])
+		values.Set("time_precision", string(precision[0]))
 	}
-	url += "&q=" + escapedQuery
-	resp, err := self.httpClient.Get(url)
+	values.Set("q", query)
+	u := self.buildUrl("/db/"+self.database+"/series", values)
+	resp, err := self.get(ctx, u)
 	err = responseToError(resp, err, false)
 	if err != nil {
 		return nil, err
@@ -272,13 +368,21 @@ func (self *Client) Query(query string, precision ...TimePrecision) ([]*Series,
 }
 
 func (self *Client) Ping() error {
-	url := self.getUrl("/ping")
-	resp, err := self.httpClient.Get(url)
+	return self.PingContext(context.Background())
+}
+
+func (self *Client) PingContext(ctx context.Context) error {
+	u := self.buildUrl("/ping", nil)
+	resp, err := self.get(ctx, u)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) AuthenticateDatabaseUser(database, username, password string) error {
-	url := self.getUrlWithUserAndPass(fmt.Sprintf("/db/%s/authenticate", database), username, password)
-	resp, err := self.httpClient.Get(url)
+	return self.AuthenticateDatabaseUserContext(context.Background(), database, username, password)
+}
+
+func (self *Client) AuthenticateDatabaseUserContext(ctx context.Context, database, username, password string) error {
+	u := self.buildUrlWithUserAndPass(fmt.Sprintf("/db/%s/authenticate", database), username, password, nil)
+	resp, err := self.get(ctx, u)
 	return responseToError(resp, err, true)
 }
@@ -0,0 +1,142 @@
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is a single line-protocol point: a measurement, its tag set, its
+// field set, and an optional timestamp.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+var measurementEscaper = strings.NewReplacer(",", "\\,", " ", "\\ ")
+var tagEscaper = strings.NewReplacer(",", "\\,", "=", "\\=", " ", "\\ ")
+var stringFieldEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+
+// scaleTimestamp converts t to the integer unit precision expects, since
+// the server interprets the trailing integer according to the precision
+// query parameter a write was sent with (nanoseconds if none was given).
+func scaleTimestamp(t time.Time, precision TimePrecision) int64 {
+	ns := t.UnixNano()
+	switch precision {
+	case Second:
+		return ns / int64(time.Second)
+	case Millisecond:
+		return ns / int64(time.Millisecond)
+	case Microsecond:
+		return ns / int64(time.Microsecond)
+	default:
+		return ns
+	}
+}
+
+func (self *Point) marshalLine(precision TimePrecision) (string, error) {
+	if len(self.Fields) == 0 {
+		return "", fmt.Errorf("influxdb: point %q has no fields", self.Measurement)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(measurementEscaper.Replace(self.Measurement))
+
+	tagKeys := make([]string, 0, len(self.Tags))
+	for k := range self.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		buf.WriteByte(',')
+		buf.WriteString(tagEscaper.Replace(k))
+		buf.WriteByte('=')
+		buf.WriteString(tagEscaper.Replace(self.Tags[k]))
+	}
+
+	fieldKeys := make([]string, 0, len(self.Fields))
+	for k := range self.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+	buf.WriteByte(' ')
+	for i, k := range fieldKeys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(tagEscaper.Replace(k))
+		buf.WriteByte('=')
+		value, err := marshalFieldValue(self.Fields[k])
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(value)
+	}
+
+	if !self.Time.IsZero() {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(scaleTimestamp(self.Time, precision), 10))
+	}
+
+	return buf.String(), nil
+}
+
+func marshalFieldValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return "\"" + stringFieldEscaper.Replace(v) + "\"", nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case int:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("influxdb: unsupported field value type %T", value)
+	}
+}
+
+// WriteLineProtocol writes points to /write using the InfluxDB line
+// protocol, which is considerably lighter on the wire than the JSON Series
+// payload used by WriteSeries.
+func (self *Client) WriteLineProtocol(points []Point, precision TimePrecision) error {
+	return self.WriteLineProtocolContext(context.Background(), points, precision)
+}
+
+// WriteLineProtocolContext is the context-aware variant of
+// WriteLineProtocol.
+func (self *Client) WriteLineProtocolContext(ctx context.Context, points []Point, precision TimePrecision) error {
+	var buf bytes.Buffer
+	for _, p := range points {
+		line, err := p.marshalLine(precision)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	values := url.Values{}
+	values.Set("db", self.database)
+	if precision != "" {
+		values.Set("precision", string(precision))
+	}
+	u := self.buildUrl("/write", values)
+
+	resp, err := self.doRequest(ctx, "POST", u, "application/octet-stream", &buf)
+	return responseToError(resp, err, true)
+}
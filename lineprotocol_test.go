@@ -0,0 +1,68 @@
+package influxdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarshalLineEscapesTagsAndMeasurement(t *testing.T) {
+	p := &Point{
+		Measurement: "cpu usage,total",
+		Tags:        map[string]string{"host": "a=b c", "region,us": "east"},
+		Fields:      map[string]interface{}{"value": 1.5},
+	}
+
+	line, err := p.marshalLine("")
+	if err != nil {
+		t.Fatalf("marshalLine returned error: %s", err)
+	}
+
+	want := `cpu\ usage\,total,host=a\=b\ c,region\,us=east value=1.5`
+	if line != want {
+		t.Errorf("marshalLine = %q, want %q", line, want)
+	}
+}
+
+func TestMarshalLineScalesTimestampToPrecision(t *testing.T) {
+	ts := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	p := &Point{
+		Measurement: "cpu",
+		Fields:      map[string]interface{}{"value": 1},
+		Time:        ts,
+	}
+
+	line, err := p.marshalLine(Second)
+	if err != nil {
+		t.Fatalf("marshalLine returned error: %s", err)
+	}
+
+	want := "cpu value=1i 1577934245"
+	if line != want {
+		t.Errorf("marshalLine = %q, want %q", line, want)
+	}
+}
+
+func TestMarshalLineFormatsFloat32WithoutDoublePrecisionNoise(t *testing.T) {
+	p := &Point{
+		Measurement: "cpu",
+		Fields:      map[string]interface{}{"value": float32(1.1)},
+	}
+
+	line, err := p.marshalLine("")
+	if err != nil {
+		t.Fatalf("marshalLine returned error: %s", err)
+	}
+
+	want := "cpu value=1.1"
+	if line != want {
+		t.Errorf("marshalLine = %q, want %q", line, want)
+	}
+}
+
+func TestMarshalLineRejectsPointWithNoFields(t *testing.T) {
+	p := &Point{Measurement: "cpu"}
+
+	if _, err := p.marshalLine(""); err == nil {
+		t.Error("marshalLine returned nil error for a point with no fields")
+	}
+}
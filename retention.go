@@ -0,0 +1,117 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// RetentionPolicy describes a retention policy as created or altered via
+// the CREATE/ALTER RETENTION POLICY InfluxQL statements.
+type RetentionPolicy struct {
+	Name               string
+	Duration           string
+	ShardGroupDuration string
+	ReplicaN           int
+	Default            bool
+}
+
+// replicaN returns the effective replication factor, defaulting to 1 since
+// InfluxDB rejects REPLICATION 0.
+func (self *RetentionPolicy) replicaN() int {
+	if self.ReplicaN <= 0 {
+		return 1
+	}
+	return self.ReplicaN
+}
+
+func (self *RetentionPolicy) statement(verb, database string) string {
+	stmt := fmt.Sprintf("%s RETENTION POLICY %s ON %s DURATION %s REPLICATION %d",
+		verb, self.Name, database, self.Duration, self.replicaN())
+	if self.ShardGroupDuration != "" {
+		stmt += fmt.Sprintf(" SHARD DURATION %s", self.ShardGroupDuration)
+	}
+	if self.Default {
+		stmt += " DEFAULT"
+	}
+	return stmt
+}
+
+// CreateRetentionPolicy creates a retention policy on database by issuing a
+// CREATE RETENTION POLICY statement through the query endpoint.
+func (self *Client) CreateRetentionPolicy(database string, rp *RetentionPolicy) error {
+	return self.CreateRetentionPolicyContext(context.Background(), database, rp)
+}
+
+func (self *Client) CreateRetentionPolicyContext(ctx context.Context, database string, rp *RetentionPolicy) error {
+	_, err := self.QueryContext(ctx, rp.statement("CREATE", database))
+	return err
+}
+
+// AlterRetentionPolicy updates an existing retention policy on database by
+// issuing an ALTER RETENTION POLICY statement through the query endpoint.
+func (self *Client) AlterRetentionPolicy(database string, rp *RetentionPolicy) error {
+	return self.AlterRetentionPolicyContext(context.Background(), database, rp)
+}
+
+func (self *Client) AlterRetentionPolicyContext(ctx context.Context, database string, rp *RetentionPolicy) error {
+	_, err := self.QueryContext(ctx, rp.statement("ALTER", database))
+	return err
+}
+
+// DeleteRetentionPolicy drops the named retention policy from database.
+func (self *Client) DeleteRetentionPolicy(database, name string) error {
+	return self.DeleteRetentionPolicyContext(context.Background(), database, name)
+}
+
+func (self *Client) DeleteRetentionPolicyContext(ctx context.Context, database, name string) error {
+	_, err := self.QueryContext(ctx, fmt.Sprintf("DROP RETENTION POLICY %s ON %s", name, database))
+	return err
+}
+
+// GetRetentionPolicies lists the retention policies defined on database.
+func (self *Client) GetRetentionPolicies(database string) ([]*RetentionPolicy, error) {
+	return self.GetRetentionPoliciesContext(context.Background(), database)
+}
+
+func (self *Client) GetRetentionPoliciesContext(ctx context.Context, database string) ([]*RetentionPolicy, error) {
+	series, err := self.QueryContext(ctx, fmt.Sprintf("SHOW RETENTION POLICIES ON %s", database))
+	if err != nil {
+		return nil, err
+	}
+	return parseRetentionPolicies(series), nil
+}
+
+// parseRetentionPolicies converts the column/point rows returned by SHOW
+// RETENTION POLICIES into RetentionPolicy values, looking columns up by
+// name since the server doesn't guarantee their order.
+func parseRetentionPolicies(series []*Series) []*RetentionPolicy {
+	policies := []*RetentionPolicy{}
+	for _, s := range series {
+		columns := make(map[string]int, len(s.Columns))
+		for i, name := range s.Columns {
+			columns[name] = i
+		}
+		for _, point := range s.Points {
+			rp := &RetentionPolicy{}
+			if i, ok := columns["name"]; ok {
+				rp.Name, _ = point[i].(string)
+			}
+			if i, ok := columns["duration"]; ok {
+				rp.Duration, _ = point[i].(string)
+			}
+			if i, ok := columns["shardGroupDuration"]; ok {
+				rp.ShardGroupDuration, _ = point[i].(string)
+			}
+			if i, ok := columns["replicaN"]; ok {
+				if n, ok := point[i].(float64); ok {
+					rp.ReplicaN = int(n)
+				}
+			}
+			if i, ok := columns["default"]; ok {
+				rp.Default, _ = point[i].(bool)
+			}
+			policies = append(policies, rp)
+		}
+	}
+	return policies
+}
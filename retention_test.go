@@ -0,0 +1,91 @@
+package influxdb
+
+import "testing"
+
+func TestRetentionPolicyStatementBuildsCreateAndAlter(t *testing.T) {
+	rp := &RetentionPolicy{
+		Name:               "30d",
+		Duration:           "30d",
+		ShardGroupDuration: "1d",
+		ReplicaN:           2,
+		Default:            true,
+	}
+
+	want := `CREATE RETENTION POLICY 30d ON mydb DURATION 30d REPLICATION 2 SHARD DURATION 1d DEFAULT`
+	if got := rp.statement("CREATE", "mydb"); got != want {
+		t.Errorf("statement(CREATE) = %q, want %q", got, want)
+	}
+
+	want = `ALTER RETENTION POLICY 30d ON mydb DURATION 30d REPLICATION 2 SHARD DURATION 1d DEFAULT`
+	if got := rp.statement("ALTER", "mydb"); got != want {
+		t.Errorf("statement(ALTER) = %q, want %q", got, want)
+	}
+}
+
+func TestRetentionPolicyStatementOmitsShardDurationAndDefault(t *testing.T) {
+	rp := &RetentionPolicy{Name: "30d", Duration: "30d"}
+
+	want := `CREATE RETENTION POLICY 30d ON mydb DURATION 30d REPLICATION 1`
+	if got := rp.statement("CREATE", "mydb"); got != want {
+		t.Errorf("statement(CREATE) = %q, want %q", got, want)
+	}
+}
+
+func TestRetentionPolicyReplicaNDefaultsToOne(t *testing.T) {
+	rp := &RetentionPolicy{}
+	if got := rp.replicaN(); got != 1 {
+		t.Errorf("replicaN() = %d, want 1", got)
+	}
+	rp.ReplicaN = 3
+	if got := rp.replicaN(); got != 3 {
+		t.Errorf("replicaN() = %d, want 3", got)
+	}
+}
+
+func TestParseRetentionPoliciesMapsColumnsByName(t *testing.T) {
+	series := []*Series{
+		{
+			Name:    "retention policies",
+			Columns: []string{"duration", "name", "replicaN", "default", "shardGroupDuration"},
+			Points: [][]interface{}{
+				{"168h0m0s", "default", float64(1), true, "24h0m0s"},
+				{"0s", "forever", float64(3), false, "168h0m0s"},
+			},
+		},
+	}
+
+	policies := parseRetentionPolicies(series)
+	if len(policies) != 2 {
+		t.Fatalf("parseRetentionPolicies returned %d policies, want 2", len(policies))
+	}
+
+	first := policies[0]
+	if first.Name != "default" || first.Duration != "168h0m0s" || first.ReplicaN != 1 || !first.Default || first.ShardGroupDuration != "24h0m0s" {
+		t.Errorf("first policy = %+v, want Name=default Duration=168h0m0s ReplicaN=1 Default=true ShardGroupDuration=24h0m0s", first)
+	}
+
+	second := policies[1]
+	if second.Name != "forever" || second.ReplicaN != 3 || second.Default {
+		t.Errorf("second policy = %+v, want Name=forever ReplicaN=3 Default=false", second)
+	}
+}
+
+func TestParseRetentionPoliciesIgnoresMissingColumns(t *testing.T) {
+	series := []*Series{
+		{
+			Columns: []string{"name"},
+			Points:  [][]interface{}{{"default"}},
+		},
+	}
+
+	policies := parseRetentionPolicies(series)
+	if len(policies) != 1 {
+		t.Fatalf("parseRetentionPolicies returned %d policies, want 1", len(policies))
+	}
+	if policies[0].Name != "default" {
+		t.Errorf("Name = %q, want %q", policies[0].Name, "default")
+	}
+	if policies[0].ReplicaN != 0 {
+		t.Errorf("ReplicaN = %d, want 0 for a missing column", policies[0].ReplicaN)
+	}
+}
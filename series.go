@@ -0,0 +1,10 @@
+package influxdb
+
+// Series is a named set of columns and rows, the unit both queries and
+// writes exchange with the server: each entry in Points holds one value
+// per column in Columns, in the same order.
+type Series struct {
+	Name    string          `json:"name"`
+	Columns []string        `json:"columns"`
+	Points  [][]interface{} `json:"points"`
+}
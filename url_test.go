@@ -0,0 +1,62 @@
+package influxdb
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestClient(t *testing.T, authMode AuthMode) *Client {
+	client, err := NewClient(&ClientConfig{
+		Host:     "example.com:8086",
+		Username: "user name",
+		Password: "p@ss/word&+%",
+		Database: "mydb",
+		AuthMode: authMode,
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %s", err)
+	}
+	return client
+}
+
+func TestBuildUrlEncodesTrickyIdentifiers(t *testing.T) {
+	client := newTestClient(t, AuthQueryString)
+
+	query := url.Values{}
+	query.Set("q", "select * from \"cpu usage\" where host = 'a&b+c% d☃'")
+	rawurl := client.buildUrl("/db/mydb/series", query)
+
+	parsed, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatalf("buildUrl produced an unparsable URL %q: %s", rawurl, err)
+	}
+
+	values := parsed.Query()
+	if got := values.Get("u"); got != client.username {
+		t.Errorf("u round-tripped to %q, want %q", got, client.username)
+	}
+	if got := values.Get("p"); got != client.password {
+		t.Errorf("p round-tripped to %q, want %q", got, client.password)
+	}
+	if got := values.Get("q"); got != query.Get("q") {
+		t.Errorf("q round-tripped to %q, want %q", got, query.Get("q"))
+	}
+}
+
+func TestBuildUrlAuthBasicOmitsCredentials(t *testing.T) {
+	client := newTestClient(t, AuthBasic)
+
+	rawurl := client.buildUrl("/db/mydb/series", nil)
+
+	parsed, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatalf("buildUrl produced an unparsable URL %q: %s", rawurl, err)
+	}
+	if parsed.RawQuery != "" {
+		t.Errorf("RawQuery = %q, want empty when AuthMode is AuthBasic", parsed.RawQuery)
+	}
+	if strings.Contains(rawurl, "user") || strings.Contains(rawurl, "p%40ss") {
+		t.Errorf("buildUrl leaked credentials into the URL in AuthBasic mode: %q", rawurl)
+	}
+}